@@ -6,8 +6,43 @@ import (
 
 // powerGrid type represents a single power grid with connected power stations
 type powerGrid struct {
-	// minHeap is a slice representing min heap data structure into which grid power stations are organized by their ids
+	// id is a stable identifier for the grid, assigned once at creation and unaffected by which
+	// stations later join or leave it via merges, used to address a grid from outside the package
+	id int
+	// minHeap is a slice representing min heap data structure into which grid's operational
+	// (online) power stations are organized by their ids. Offline stations are evicted from
+	// minHeap by moveOffline and reinserted by moveOnline.
 	minHeap []*powerStation
+	// members holds every power station currently belonging to the grid, online or offline. It exists
+	// because minHeap no longer tracks offline stations, yet a merge still needs the complete
+	// roster to reseat every station's grid pointer.
+	members []*powerStation
+	// totalSize is the number of power stations belonging to the grid, online or offline
+	totalSize int
+	// onlineCount is the number of those power stations that are currently online
+	onlineCount int
+	// minOperationalSize is the configured online-count threshold below which the grid is
+	// considered at risk; zero means no threshold is configured
+	minOperationalSize int
+}
+
+// mergeGrids merges "grid" and "other" by concatenating the min-heap (and roster) of the grid with
+// fewer members into the other one and re-heapifying, reseating the grid pointer of every migrated
+// power station along the way. It returns the grid that survives the merge.
+func mergeGrids(grid, other *powerGrid) *powerGrid {
+	bigGrid, smallGrid := grid, other
+	if len(smallGrid.members) > len(bigGrid.members) {
+		bigGrid, smallGrid = smallGrid, bigGrid
+	}
+	for _, station := range smallGrid.members {
+		station.grid = bigGrid
+	}
+	bigGrid.members = append(bigGrid.members, smallGrid.members...)
+	bigGrid.minHeap = append(bigGrid.minHeap, smallGrid.minHeap...)
+	bigGrid.totalSize += smallGrid.totalSize
+	bigGrid.onlineCount += smallGrid.onlineCount
+	bigGrid.buildHeap()
+	return bigGrid
 }
 
 // addPowerStation adds power station with the specified id to power grid
@@ -16,7 +51,11 @@ func (grid *powerGrid) addPowerStation(stationID int, stationPool *powerStationP
 	station.grid = grid
 	station.id = stationID
 	station.online = true
+	station.heapIndex = len(grid.minHeap)
 	grid.minHeap = append(grid.minHeap, station)
+	grid.members = append(grid.members, station)
+	grid.totalSize++
+	grid.onlineCount++
 }
 
 // siftDown moves the power station located at the index "index" in min-heap slice down the min heap tree
@@ -37,40 +76,120 @@ func (grid *powerGrid) siftDown(index int) {
 			break
 		}
 		grid.minHeap[smallerChildIndex], grid.minHeap[index] = grid.minHeap[index], grid.minHeap[smallerChildIndex]
+		grid.minHeap[smallerChildIndex].heapIndex, grid.minHeap[index].heapIndex = smallerChildIndex, index
 		index = smallerChildIndex
 	}
 }
 
-// buildHeap urilizes siftDown to implement heapify method restoring min-heap property
+// siftUp moves the power station located at the index "index" in min-heap slice up the min heap tree
+// by successively exchanging this power station with its parent node while the parent holds a larger id
+func (grid *powerGrid) siftUp(index int) {
+	for index > 0 {
+		parentIndex := (index - 1) >> 1
+		if grid.minHeap[parentIndex].id <= grid.minHeap[index].id {
+			break
+		}
+		grid.minHeap[parentIndex], grid.minHeap[index] = grid.minHeap[index], grid.minHeap[parentIndex]
+		grid.minHeap[parentIndex].heapIndex, grid.minHeap[index].heapIndex = parentIndex, index
+		index = parentIndex
+	}
+}
+
+// buildHeap utilizes siftDown to implement heapify method restoring min-heap property. It first
+// resyncs every station's heapIndex to its current slice position, since callers may have
+// concatenated min-heaps together before invoking it
 func (grid *powerGrid) buildHeap() {
+	for i, station := range grid.minHeap {
+		station.heapIndex = i
+	}
 	for i := len(grid.minHeap)>>1 - 1; i >= 0; i-- {
 		grid.siftDown(i)
 	}
 }
 
-// removeMin removes power station with the smallest id from the grid
-func (grid *powerGrid) removeMin() {
+// remove removes the power station located at index "index" from the min-heap slice, moving the
+// last element into its place and sifting it in either direction to restore the min-heap property
+func (grid *powerGrid) remove(index int) {
 	lastIndex := len(grid.minHeap) - 1
-	grid.minHeap[0] = grid.minHeap[lastIndex]
+	grid.minHeap[index] = grid.minHeap[lastIndex]
+	grid.minHeap[index].heapIndex = index
 	grid.minHeap = grid.minHeap[:lastIndex]
-	grid.siftDown(0)
+	if index < len(grid.minHeap) {
+		grid.siftDown(index)
+		grid.siftUp(index)
+	}
 }
 
-// getMin retrieves power station with the smallest id in the grid
-func (grid *powerGrid) getMin() *powerStation {
-	return grid.minHeap[0]
+// decreaseKey restores the min-heap property after the power station at index "index" has had
+// its effective key reduced. It is also what reinserting a station amounts to: appending it at
+// the end of minHeap and calling decreaseKey sifts it up from its place-holder position into the
+// spot its id actually belongs in
+func (grid *powerGrid) decreaseKey(index int) {
+	grid.siftUp(index)
 }
 
-// getOperationalStationMinID returns operational power station with the smallest id in the grid
+// getOperationalStationMinID returns operational power station with the smallest id in the grid,
+// or -1 if every station in the grid is currently offline
 func (grid *powerGrid) getOperationalStationMinID() int {
-	for len(grid.minHeap) > 0 {
-		stationWithMinID := grid.getMin()
-		if stationWithMinID.online {
-			return stationWithMinID.id
+	if len(grid.minHeap) == 0 {
+		return -1
+	}
+	return grid.minHeap[0].id
+}
+
+// kSmallestEntry pairs a power station id with its position in the owning grid's minHeap,
+// used while walking the heap for k-nearest style queries
+type kSmallestEntry struct {
+	id    int
+	index int
+}
+
+// kSmallestHeap is an auxiliary min-heap (ordered by id) of kSmallestEntry values, kept separate
+// from powerGrid's own minHeap since it tracks heap positions rather than power stations
+type kSmallestHeap []kSmallestEntry
+
+// siftDown restores the min-heap property below index "i"
+func (h kSmallestHeap) siftDown(i int) {
+	length := len(h)
+	for {
+		leftIndex, rightIndex := i<<1+1, i<<1+2
+		if leftIndex >= length {
+			return
+		}
+		smallerChildIndex := leftIndex
+		if rightIndex < length && h[rightIndex].id < h[leftIndex].id {
+			smallerChildIndex = rightIndex
+		}
+		if h[smallerChildIndex].id >= h[i].id {
+			return
 		}
-		grid.removeMin()
+		h[smallerChildIndex], h[i] = h[i], h[smallerChildIndex]
+		i = smallerChildIndex
+	}
+}
+
+// push appends entry and sifts it up into position
+func (h *kSmallestHeap) push(entry kSmallestEntry) {
+	*h = append(*h, entry)
+	index := len(*h) - 1
+	for index > 0 {
+		parentIndex := (index - 1) >> 1
+		if (*h)[parentIndex].id <= (*h)[index].id {
+			break
+		}
+		(*h)[parentIndex], (*h)[index] = (*h)[index], (*h)[parentIndex]
+		index = parentIndex
 	}
-	return -1
+}
+
+// pop removes and returns the smallest entry
+func (h *kSmallestHeap) pop() kSmallestEntry {
+	top := (*h)[0]
+	lastIndex := len(*h) - 1
+	(*h)[0] = (*h)[lastIndex]
+	*h = (*h)[:lastIndex]
+	h.siftDown(0)
+	return top
 }
 
 // powerStation type represents a single power station that is part of a power grid
@@ -81,6 +200,9 @@ type powerStation struct {
 	id int
 	// online is true when power station is online and false if it is offline
 	online bool
+	// heapIndex is this station's current position in its grid's minHeap slice, kept in sync
+	// by siftDown/siftUp so remove/decreaseKey can locate it without a linear scan
+	heapIndex int
 }
 
 // resolveMaintenanceCheck resolves maintenance check request to the power station
@@ -91,9 +213,27 @@ func (station *powerStation) resolveMaintenanceCheck() int {
 	return station.grid.getOperationalStationMinID()
 }
 
-// moveOffline moves the power station offline
+// moveOffline moves the power station offline, evicting it from its grid's min-heap
 func (station *powerStation) moveOffline() {
+	if !station.online {
+		return
+	}
 	station.online = false
+	station.grid.remove(station.heapIndex)
+	station.grid.onlineCount--
+}
+
+// moveOnline brings the power station back online, reinserting it into its grid's min-heap
+func (station *powerStation) moveOnline() {
+	if station.online {
+		return
+	}
+	station.online = true
+	grid := station.grid
+	station.heapIndex = len(grid.minHeap)
+	grid.minHeap = append(grid.minHeap, station)
+	grid.decreaseKey(station.heapIndex)
+	grid.onlineCount++
 }
 
 // powerStationPool represents the contiguous pool of power stations indexed by their ids
@@ -113,67 +253,297 @@ func (pool *powerStationPool) get(stationID int) *powerStation {
 	return station
 }
 
+// stagedChangeKind identifies the kind of a queued, not-yet-applied topology mutation
+type stagedChangeKind int
+
+const (
+	stagedConnect stagedChangeKind = iota
+	stagedDisconnect
+	stagedOffline
+	stagedOnline
+)
+
+// stagedChange represents a single queued topology mutation
+type stagedChange struct {
+	kind stagedChangeKind
+	a, b int
+}
+
 // PowerGridInterconnection represents the power grid interconnection consisting of
 // the pool of all interconnection power stations and the collection of disconnected power grids
 type PowerGridInterconnection struct {
 	stationPool *powerStationPool
 	grids       []*powerGrid
+	// parent is the disjoint-set forest's parent pointers, indexed by power station id
+	parent []int
+	// rank is the disjoint-set forest's per-root rank, used for union-by-rank
+	rank []int
+	// adjacency is the transmission line graph, indexed by power station id. It is kept
+	// alongside the disjoint-set forest because union-find has no way to undo a union, so
+	// detecting whether disconnecting a line splits a grid requires the actual edge list
+	adjacency [][]int
+	// nextGridID assigns stable ids to power grids created after construction (e.g. when a
+	// disconnect splits an existing grid in two), picking up where the initial per-station
+	// grid ids leave off
+	nextGridID int
+	// staged holds topology mutations queued by the Stage* methods until ApplyStaged or
+	// DiscardStaged is called
+	staged []stagedChange
+	// events is the channel GridEvent values are emitted on as grids cross their configured
+	// MinOperationalSize threshold
+	events chan GridEvent
+}
+
+// GridEvent reports that a power grid's online station count has crossed its configured
+// MinOperationalSize threshold, in either direction
+type GridEvent struct {
+	// GridID identifies the grid that crossed its threshold
+	GridID int
+	// OnlineCount is the grid's online station count at the time of the crossing
+	OnlineCount int
+	// MinThreshold is the grid's configured minimum operational size
+	MinThreshold int
+	// BelowThreshold is true if the grid just dropped below MinThreshold, false if it just
+	// recovered back to or above it
+	BelowThreshold bool
 }
 
 // NewPowerGridInterconnection constructs new power grid interconnection
 // from 2D array "connections" representing the connections between power stations
 func NewPowerGridInterconnection(c int, connections [][]int) *PowerGridInterconnection {
-	// build adjacency list of the entire interconnection
-	graph := make([][]int, c+1)
-	for _, edge := range connections {
-		node1, node2 := edge[0], edge[1]
-		graph[node1] = append(graph[node1], node2)
-		graph[node2] = append(graph[node2], node1)
-	}
 	// create a pool of power stations in interconnection
 	stationPool := newStationPool(c)
-	// create a slice for power grids in interconnection
-	grids := []*powerGrid{}
-	// visited represents power station visitation slice in DFS process finding power stations connected into power grids
-	visited := make([]bool, c+1)
-	// DFS for finding connected components in the graph
+	parent := make([]int, c+1)
 	for nd := 1; nd <= c; nd++ {
-		if visited[nd] {
-			continue
-		}
-		// create new grid
-		grid := &powerGrid{}
-		// add grid to interconnection
-		grids = append(grids, grid)
-		stack := []int{nd}
-		for len(stack) > 0 {
-			node := stack[len(stack)-1]
-			stack = stack[:len(stack)-1]
-			if visited[node] {
+		parent[nd] = nd
+	}
+	interconnection := &PowerGridInterconnection{
+		stationPool: stationPool,
+		parent:      parent,
+		rank:        make([]int, c+1),
+		adjacency:   make([][]int, c+1),
+		nextGridID:  c + 1,
+		events:      make(chan GridEvent, 64),
+	}
+	// every power station starts out in its own single-station grid
+	interconnection.grids = make([]*powerGrid, 0, c)
+	for nd := 1; nd <= c; nd++ {
+		grid := &powerGrid{id: nd}
+		grid.addPowerStation(nd, stationPool)
+		interconnection.grids = append(interconnection.grids, grid)
+	}
+	// wiring up "connections" via the disjoint-set forest merges the single-station
+	// grids into the interconnection's actual connected components
+	for _, edge := range connections {
+		interconnection.ConnectStations(edge[0], edge[1])
+	}
+	return interconnection
+}
+
+// getStationByID fetches interconnection power station by its id
+func (interconnection *PowerGridInterconnection) getStationByID(stationID int) *powerStation {
+	return interconnection.stationPool.get(stationID)
+}
+
+// Find returns the disjoint-set root representing the power station id's connected
+// component, compressing the path to that root along the way
+func (interconnection *PowerGridInterconnection) Find(stationID int) int {
+	if interconnection.parent[stationID] != stationID {
+		interconnection.parent[stationID] = interconnection.Find(interconnection.parent[stationID])
+	}
+	return interconnection.parent[stationID]
+}
+
+// union merges the disjoint-set components rooted at a and b using union-by-rank
+func (interconnection *PowerGridInterconnection) union(a, b int) {
+	rootA, rootB := interconnection.Find(a), interconnection.Find(b)
+	if rootA == rootB {
+		return
+	}
+	if interconnection.rank[rootA] < interconnection.rank[rootB] {
+		rootA, rootB = rootB, rootA
+	}
+	interconnection.parent[rootB] = rootA
+	if interconnection.rank[rootA] == interconnection.rank[rootB] {
+		interconnection.rank[rootA]++
+	}
+}
+
+// removeGrid drops "grid" from the interconnection's grid collection
+func (interconnection *PowerGridInterconnection) removeGrid(grid *powerGrid) {
+	for i, g := range interconnection.grids {
+		if g == grid {
+			lastIndex := len(interconnection.grids) - 1
+			interconnection.grids[i] = interconnection.grids[lastIndex]
+			interconnection.grids = interconnection.grids[:lastIndex]
+			return
+		}
+	}
+}
+
+// addEdge records a transmission line between power stations a and b in the interconnection's
+// adjacency list
+func (interconnection *PowerGridInterconnection) addEdge(a, b int) {
+	interconnection.adjacency[a] = append(interconnection.adjacency[a], b)
+	interconnection.adjacency[b] = append(interconnection.adjacency[b], a)
+}
+
+// removeEdge deletes the transmission line between power stations a and b from the
+// interconnection's adjacency list
+func (interconnection *PowerGridInterconnection) removeEdge(a, b int) {
+	interconnection.adjacency[a] = removeValue(interconnection.adjacency[a], b)
+	interconnection.adjacency[b] = removeValue(interconnection.adjacency[b], a)
+}
+
+// removeValue returns values with the first occurrence of target removed
+func removeValue(values []int, target int) []int {
+	for i, v := range values {
+		if v == target {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// bfsComponent returns the set of power station ids reachable from "start" via the
+// interconnection's current adjacency list
+func (interconnection *PowerGridInterconnection) bfsComponent(start int) map[int]bool {
+	visited := map[int]bool{start: true}
+	queue := []int{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, neighbor := range interconnection.adjacency[node] {
+			if visited[neighbor] {
 				continue
 			}
-			grid.addPowerStation(node, stationPool)
-			visited[node] = true
-			for _, neighbor := range graph[node] {
-				if visited[neighbor] {
-					continue
-				}
-				stack = append(stack, neighbor)
+			visited[neighbor] = true
+			queue = append(queue, neighbor)
+		}
+	}
+	return visited
+}
+
+// rebuildUnionFind resets the disjoint-set parent/rank entries for every station id in
+// "component" so that Find once again reports "root" as their common representative
+func (interconnection *PowerGridInterconnection) rebuildUnionFind(component map[int]bool, root int) {
+	for stationID := range component {
+		interconnection.parent[stationID] = root
+		interconnection.rank[stationID] = 0
+	}
+	interconnection.rank[root] = 1
+}
+
+// ConnectStations adds a new transmission line between power stations a and b. If they
+// belonged to separate power grids, the two grids are merged into one
+func (interconnection *PowerGridInterconnection) ConnectStations(a, b int) {
+	interconnection.addEdge(a, b)
+	rootA, rootB := interconnection.Find(a), interconnection.Find(b)
+	if rootA == rootB {
+		return
+	}
+	gridA := interconnection.getStationByID(rootA).grid
+	gridB := interconnection.getStationByID(rootB).grid
+	interconnection.union(rootA, rootB)
+	survivingGrid := mergeGrids(gridA, gridB)
+	absorbedGrid := gridA
+	if survivingGrid == gridA {
+		absorbedGrid = gridB
+	}
+	interconnection.removeGrid(absorbedGrid)
+}
+
+// DisconnectStations removes the transmission line between power stations a and b. If that was
+// the only path connecting them, their shared power grid is split in two: a targeted BFS from a
+// (over the updated adjacency list) identifies a's component, the rest of the grid forms b's
+// component, and each is rebuilt with its own min-heap and disjoint-set root
+func (interconnection *PowerGridInterconnection) DisconnectStations(a, b int) {
+	interconnection.removeEdge(a, b)
+	grid := interconnection.getStationByID(a).grid
+	reachableFromA := interconnection.bfsComponent(a)
+	if reachableFromA[b] {
+		return
+	}
+	splitGrid := &powerGrid{id: interconnection.nextGridID}
+	interconnection.nextGridID++
+	remaining := grid.members[:0:0]
+	for _, station := range grid.members {
+		if reachableFromA[station.id] {
+			station.grid = splitGrid
+			splitGrid.members = append(splitGrid.members, station)
+			splitGrid.totalSize++
+			if station.online {
+				splitGrid.minHeap = append(splitGrid.minHeap, station)
+				splitGrid.onlineCount++
 			}
+		} else {
+			remaining = append(remaining, station)
 		}
 	}
-	for _, grid := range grids {
-		grid.buildHeap()
+	grid.members = remaining
+	grid.totalSize -= splitGrid.totalSize
+	grid.onlineCount -= splitGrid.onlineCount
+	onlineRemaining := grid.minHeap[:0:0]
+	for _, station := range grid.minHeap {
+		if !reachableFromA[station.id] {
+			onlineRemaining = append(onlineRemaining, station)
+		}
 	}
-	return &PowerGridInterconnection{
-		stationPool: stationPool,
-		grids:       grids,
+	grid.minHeap = onlineRemaining
+	splitGrid.buildHeap()
+	grid.buildHeap()
+	interconnection.grids = append(interconnection.grids, splitGrid)
+	interconnection.rebuildUnionFind(reachableFromA, a)
+	reachableFromB := make(map[int]bool, len(remaining))
+	for _, station := range remaining {
+		reachableFromB[station.id] = true
 	}
+	interconnection.rebuildUnionFind(reachableFromB, b)
 }
 
-// getStationByID fetches interconnection power station by its id
-func (interconnection *PowerGridInterconnection) getStationByID(stationID int) *powerStation {
-	return interconnection.stationPool.get(stationID)
+// StageConnect queues a new transmission line between power stations a and b to be created
+// when ApplyStaged runs. Until then it is not observable to maintenance check queries
+func (interconnection *PowerGridInterconnection) StageConnect(a, b int) {
+	interconnection.staged = append(interconnection.staged, stagedChange{kind: stagedConnect, a: a, b: b})
+}
+
+// StageDisconnect queues the removal of the transmission line between power stations a and b,
+// to take effect when ApplyStaged runs
+func (interconnection *PowerGridInterconnection) StageDisconnect(a, b int) {
+	interconnection.staged = append(interconnection.staged, stagedChange{kind: stagedDisconnect, a: a, b: b})
+}
+
+// StageOffline queues taking the power station with the specified id offline
+func (interconnection *PowerGridInterconnection) StageOffline(stationID int) {
+	interconnection.staged = append(interconnection.staged, stagedChange{kind: stagedOffline, a: stationID})
+}
+
+// StageOnline queues bringing the power station with the specified id back online
+func (interconnection *PowerGridInterconnection) StageOnline(stationID int) {
+	interconnection.staged = append(interconnection.staged, stagedChange{kind: stagedOnline, a: stationID})
+}
+
+// DiscardStaged clears every queued topology mutation without applying any of them
+func (interconnection *PowerGridInterconnection) DiscardStaged() {
+	interconnection.staged = nil
+}
+
+// ApplyStaged commits every queued topology mutation, in the order it was staged, recomputing
+// affected grids as it goes
+func (interconnection *PowerGridInterconnection) ApplyStaged() {
+	for _, change := range interconnection.staged {
+		switch change.kind {
+		case stagedConnect:
+			interconnection.ConnectStations(change.a, change.b)
+		case stagedDisconnect:
+			interconnection.DisconnectStations(change.a, change.b)
+		case stagedOffline:
+			interconnection.MoveStationOffline(change.a)
+		case stagedOnline:
+			interconnection.MoveStationOnline(change.a)
+		}
+	}
+	interconnection.staged = nil
 }
 
 // ResolveMaintenanceCheckForStation resolves maintenance check request to the power station with the specified id
@@ -181,9 +551,138 @@ func (interconnection *PowerGridInterconnection) ResolveMaintenanceCheckForStati
 	return interconnection.getStationByID(stationID).resolveMaintenanceCheck()
 }
 
-// MoveStationOffline moves offline the power station with the specified id
+// ResolveKMaintenanceChecksForStation returns up to k operational power station ids, in ascending
+// order, from the same grid as the power station with the specified id
+func (interconnection *PowerGridInterconnection) ResolveKMaintenanceChecksForStation(stationID, k int) []int {
+	grid := interconnection.getStationByID(stationID).grid
+	results := []int{}
+	if k <= 0 || len(grid.minHeap) == 0 {
+		return results
+	}
+	aux := kSmallestHeap{{id: grid.minHeap[0].id, index: 0}}
+	for len(aux) > 0 && len(results) < k {
+		entry := aux.pop()
+		results = append(results, entry.id)
+		leftIndex, rightIndex := entry.index<<1+1, entry.index<<1+2
+		if leftIndex < len(grid.minHeap) {
+			aux.push(kSmallestEntry{id: grid.minHeap[leftIndex].id, index: leftIndex})
+		}
+		if rightIndex < len(grid.minHeap) {
+			aux.push(kSmallestEntry{id: grid.minHeap[rightIndex].id, index: rightIndex})
+		}
+	}
+	return results
+}
+
+// MaintenanceCriteria narrows down which power stations are eligible to satisfy a maintenance check
+type MaintenanceCriteria struct {
+	// ExcludedIDs lists power station ids that must not be selected
+	ExcludedIDs []int
+	// ExcludedGrids lists power grid ids (the grid's stable id, as in GridEvent.GridID) that
+	// must not be selected from
+	ExcludedGrids []int
+	// RequireOnline, when true, only considers stations that are currently online
+	RequireOnline bool
+}
+
+// ResolveMaintenanceCheckWithCriteria resolves a maintenance check for the power station with the
+// specified id, skipping any candidate excluded by crit, or returns -1 if nothing in the grid
+// satisfies it
+func (interconnection *PowerGridInterconnection) ResolveMaintenanceCheckWithCriteria(stationID int, crit MaintenanceCriteria) int {
+	grid := interconnection.getStationByID(stationID).grid
+	excludedGrids := make(map[int]bool, len(crit.ExcludedGrids))
+	for _, gridIndex := range crit.ExcludedGrids {
+		excludedGrids[gridIndex] = true
+	}
+	if excludedGrids[grid.id] || len(grid.minHeap) == 0 {
+		return -1
+	}
+	excludedIDs := make(map[int]bool, len(crit.ExcludedIDs))
+	for _, id := range crit.ExcludedIDs {
+		excludedIDs[id] = true
+	}
+	aux := kSmallestHeap{{id: grid.minHeap[0].id, index: 0}}
+	for len(aux) > 0 {
+		entry := aux.pop()
+		station := grid.minHeap[entry.index]
+		if !excludedIDs[station.id] && (!crit.RequireOnline || station.online) {
+			return station.id
+		}
+		leftIndex, rightIndex := entry.index<<1+1, entry.index<<1+2
+		if leftIndex < len(grid.minHeap) {
+			aux.push(kSmallestEntry{id: grid.minHeap[leftIndex].id, index: leftIndex})
+		}
+		if rightIndex < len(grid.minHeap) {
+			aux.push(kSmallestEntry{id: grid.minHeap[rightIndex].id, index: rightIndex})
+		}
+	}
+	return -1
+}
+
+// MoveStationOffline moves offline the power station with the specified id, emitting a GridEvent
+// if doing so drops its grid's online count below its configured MinOperationalSize
 func (interconnection *PowerGridInterconnection) MoveStationOffline(stationID int) {
-	interconnection.getStationByID(stationID).moveOffline()
+	station := interconnection.getStationByID(stationID)
+	grid := station.grid
+	wasBelow := interconnection.isBelowThreshold(grid)
+	station.moveOffline()
+	interconnection.emitIfThresholdCrossed(grid, wasBelow)
+}
+
+// MoveStationOnline brings the power station with the specified id back online, emitting a
+// GridEvent if doing so recovers its grid's online count back to its configured MinOperationalSize
+func (interconnection *PowerGridInterconnection) MoveStationOnline(stationID int) {
+	station := interconnection.getStationByID(stationID)
+	grid := station.grid
+	wasBelow := interconnection.isBelowThreshold(grid)
+	station.moveOnline()
+	interconnection.emitIfThresholdCrossed(grid, wasBelow)
+}
+
+// isBelowThreshold reports whether grid's online count is currently under its configured
+// MinOperationalSize. A grid with no threshold configured (minOperationalSize == 0) is never
+// considered below threshold
+func (interconnection *PowerGridInterconnection) isBelowThreshold(grid *powerGrid) bool {
+	return grid.minOperationalSize > 0 && grid.onlineCount < grid.minOperationalSize
+}
+
+// emitIfThresholdCrossed sends a GridEvent on the interconnection's events channel if grid's
+// below-threshold state changed from wasBelow to its current state, without blocking if nobody
+// is listening
+func (interconnection *PowerGridInterconnection) emitIfThresholdCrossed(grid *powerGrid, wasBelow bool) {
+	isBelow := interconnection.isBelowThreshold(grid)
+	if isBelow == wasBelow {
+		return
+	}
+	event := GridEvent{
+		GridID:         grid.id,
+		OnlineCount:    grid.onlineCount,
+		MinThreshold:   grid.minOperationalSize,
+		BelowThreshold: isBelow,
+	}
+	select {
+	case interconnection.events <- event:
+	default:
+	}
+}
+
+// SetMinOperationalSize configures the minimum online station count for the grid containing the
+// power station with the specified id
+func (interconnection *PowerGridInterconnection) SetMinOperationalSize(stationID, minSize int) {
+	interconnection.getStationByID(stationID).grid.minOperationalSize = minSize
+}
+
+// GridStats returns the total station count, online station count, and configured minimum
+// operational size for the grid containing the power station with the specified id
+func (interconnection *PowerGridInterconnection) GridStats(stationID int) (total, online, minThreshold int) {
+	grid := interconnection.getStationByID(stationID).grid
+	return grid.totalSize, grid.onlineCount, grid.minOperationalSize
+}
+
+// Events returns the channel on which GridEvent values are emitted as grids cross below or back
+// above their configured MinOperationalSize threshold
+func (interconnection *PowerGridInterconnection) Events() <-chan GridEvent {
+	return interconnection.events
 }
 
 // processQueries constructs power grid interconnections from 2D array "connections"
@@ -198,6 +697,10 @@ func processQueries(c int, connections [][]int, queries [][]int) []int {
 			result = append(result, interconnection.ResolveMaintenanceCheckForStation(stationID))
 		case 2:
 			interconnection.MoveStationOffline(stationID)
+		case 3:
+			interconnection.ConnectStations(stationID, query[2])
+		case 4:
+			interconnection.MoveStationOnline(stationID)
 		default:
 		}
 	}